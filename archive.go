@@ -0,0 +1,354 @@
+package toolkit
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrZipSlip is returned when an archive entry's path would resolve outside
+// the destination directory once cleaned and joined.
+var ErrZipSlip = errors.New("archive entry path escapes destination directory")
+
+// ErrArchiveTooLarge is returned when the total uncompressed size of an
+// archive's entries would exceed MaxTotalUncompressedSize.
+var ErrArchiveTooLarge = errors.New("archive uncompressed size exceeds limit")
+
+// UploadAndExtractArchive accepts a single multipart-uploaded .zip or
+// .tar.gz file, stores it, then extracts its entries into uploadDir. Each
+// entry is validated before being written: absolute paths and paths that
+// escape uploadDir after filepath.Join+filepath.Clean are rejected
+// (zip-slip), entries are sniffed by their first 512 bytes and checked
+// against AllowedFileTypes, each entry is capped by MaxFileSize, and the sum
+// of all entries is capped by MaxTotalUncompressedSize.
+func (t *Tools) UploadAndExtractArchive(r *http.Request, uploadDir string) ([]*UploadedFile, error) {
+	// AllowedFileTypes governs the entries inside the archive, not the
+	// archive container itself, so the container is saved directly rather
+	// than through UploadFiles.
+	archivePath, originalFilename, err := t.saveArchiveUpload(r, uploadDir)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(archivePath)
+
+	switch {
+	case strings.HasSuffix(strings.ToLower(originalFilename), ".zip"):
+		return t.extractZip(archivePath, uploadDir)
+	case strings.HasSuffix(strings.ToLower(originalFilename), ".tar.gz"),
+		strings.HasSuffix(strings.ToLower(originalFilename), ".tgz"):
+		return t.extractTarGz(archivePath, uploadDir)
+	default:
+		return nil, errors.New("unsupported archive type")
+	}
+}
+
+// saveArchiveUpload parses the multipart request body and writes the first
+// file part to uploadDir verbatim, returning its stored path and original
+// filename. Unlike UploadFiles it does not apply AllowedFileTypes, since that
+// option is reserved for validating the archive's extracted entries.
+func (t *Tools) saveArchiveUpload(r *http.Request, uploadDir string) (string, string, error) {
+	if err := r.ParseMultipartForm(int64(t.MaxFileSize)); err != nil {
+		return "", "", errors.New("file size is too large")
+	}
+
+	for _, fhdr := range r.MultipartForm.File {
+		for _, hdr := range fhdr {
+			infile, err := hdr.Open()
+			if err != nil {
+				return "", "", err
+			}
+			defer infile.Close()
+
+			newFilename := fmt.Sprintf("%s%s", t.RandomString(25), filepath.Ext(hdr.Filename))
+			destPath := filepath.Join(uploadDir, newFilename)
+
+			outfile, err := os.Create(destPath)
+			if err != nil {
+				return "", "", err
+			}
+			defer outfile.Close()
+
+			if _, err := io.Copy(outfile, infile); err != nil {
+				return "", "", err
+			}
+
+			return destPath, hdr.Filename, nil
+		}
+	}
+
+	return "", "", errors.New("no archive file found in request")
+}
+
+// totalBudget returns a shared remaining-bytes counter for
+// MaxTotalUncompressedSize, or nil when it is unset (unlimited), so
+// extractEntry can enforce the cap while writing instead of after the fact.
+func (t *Tools) totalBudget() *int64 {
+	if t.MaxTotalUncompressedSize <= 0 {
+		return nil
+	}
+	remaining := int64(t.MaxTotalUncompressedSize)
+	return &remaining
+}
+
+// removeExtractedFiles deletes previously extracted entries, used to clean
+// up a partially extracted archive once a later entry is rejected.
+func removeExtractedFiles(paths []string) {
+	for _, p := range paths {
+		_ = os.Remove(p)
+	}
+}
+
+func (t *Tools) extractZip(archivePath, uploadDir string) ([]*UploadedFile, error) {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var uploadedFiles []*UploadedFile
+	var destPaths []string
+	remaining := t.totalBudget()
+
+	for _, entry := range zr.File {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+
+		destPath, err := t.safeExtractPath(uploadDir, entry.Name)
+		if err != nil {
+			removeExtractedFiles(destPaths)
+			return nil, err
+		}
+
+		rc, err := entry.Open()
+		if err != nil {
+			removeExtractedFiles(destPaths)
+			return nil, err
+		}
+
+		uploaded, err := t.extractEntry(rc, destPath, entry.Name, remaining)
+		rc.Close()
+		if err != nil {
+			removeExtractedFiles(destPaths)
+			return nil, err
+		}
+
+		destPaths = append(destPaths, destPath)
+		uploadedFiles = append(uploadedFiles, uploaded)
+	}
+
+	return uploadedFiles, nil
+}
+
+func (t *Tools) extractTarGz(archivePath, uploadDir string) ([]*UploadedFile, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+
+	var uploadedFiles []*UploadedFile
+	var destPaths []string
+	remaining := t.totalBudget()
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			removeExtractedFiles(destPaths)
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		destPath, err := t.safeExtractPath(uploadDir, hdr.Name)
+		if err != nil {
+			removeExtractedFiles(destPaths)
+			return nil, err
+		}
+
+		uploaded, err := t.extractEntry(tr, destPath, hdr.Name, remaining)
+		if err != nil {
+			removeExtractedFiles(destPaths)
+			return nil, err
+		}
+
+		destPaths = append(destPaths, destPath)
+		uploadedFiles = append(uploadedFiles, uploaded)
+	}
+
+	return uploadedFiles, nil
+}
+
+// safeExtractPath rejects absolute paths and entries that would escape
+// uploadDir once cleaned, returning the safe destination path otherwise.
+func (t *Tools) safeExtractPath(uploadDir, entryName string) (string, error) {
+	if filepath.IsAbs(entryName) {
+		return "", ErrZipSlip
+	}
+
+	destPath := filepath.Join(uploadDir, entryName)
+	cleanUploadDir := filepath.Clean(uploadDir) + string(os.PathSeparator)
+	if !strings.HasPrefix(filepath.Clean(destPath)+string(os.PathSeparator), cleanUploadDir) {
+		return "", ErrZipSlip
+	}
+
+	return destPath, nil
+}
+
+// extractEntry validates and writes a single archive entry's contents,
+// enforcing MaxFileSize, AllowedFileTypes and the shared MaxTotalUncompressedSize
+// budget (remaining, nil when unset). It returns the uploaded file record.
+func (t *Tools) extractEntry(src io.Reader, destPath, entryName string, remaining *int64) (*UploadedFile, error) {
+	if err := os.MkdirAll(filepath.Dir(destPath), 0o755); err != nil {
+		return nil, err
+	}
+
+	buff := make([]byte, 512)
+	n, err := io.ReadFull(src, buff)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	buff = buff[:n]
+	filetype := http.DetectContentType(buff)
+
+	if len(t.AllowedFileTypes) > 0 {
+		isFileAllowed := false
+		for _, ft := range t.AllowedFileTypes {
+			if strings.EqualFold(filetype, ft) {
+				isFileAllowed = true
+			}
+		}
+		if !isFileAllowed {
+			return nil, fmt.Errorf("%s: %w", entryName, errors.New("uploading filetype is not permitted"))
+		}
+	}
+
+	outfile, err := os.Create(destPath)
+	if err != nil {
+		return nil, err
+	}
+	defer outfile.Close()
+
+	rest := io.MultiReader(strings.NewReader(string(buff)), src)
+
+	var copyFrom io.Reader = rest
+	if t.MaxFileSize > 0 {
+		copyFrom = &io.LimitedReader{R: rest, N: int64(t.MaxFileSize) + 1}
+	}
+
+	written, err := copyWithBudget(outfile, copyFrom, remaining)
+	if err != nil {
+		os.Remove(destPath)
+		return nil, err
+	}
+	if t.MaxFileSize > 0 && written > int64(t.MaxFileSize) {
+		os.Remove(destPath)
+		return nil, fmt.Errorf("%s: %w", entryName, errors.New("file size is too large"))
+	}
+
+	return &UploadedFile{
+		OriginalFilename: entryName,
+		NewFilename:      filepath.Base(destPath),
+		FileSize:         written,
+	}, nil
+}
+
+// copyWithBudget copies src to dst like io.Copy, except when remaining is
+// non-nil it decrements the shared budget as bytes are written and aborts
+// with ErrArchiveTooLarge as soon as the budget runs out, so an oversized
+// entry is caught mid-write instead of after it's already fully on disk.
+func copyWithBudget(dst io.Writer, src io.Reader, remaining *int64) (int64, error) {
+	if remaining == nil {
+		return io.Copy(dst, src)
+	}
+
+	var written int64
+	buf := make([]byte, 32*1024)
+	for {
+		if *remaining <= 0 {
+			n, err := src.Read(buf[:1])
+			if n > 0 {
+				return written, ErrArchiveTooLarge
+			}
+			if err == io.EOF {
+				return written, nil
+			}
+			return written, err
+		}
+
+		chunk := int64(len(buf))
+		if *remaining < chunk {
+			chunk = *remaining
+		}
+
+		n, rerr := src.Read(buf[:chunk])
+		if n > 0 {
+			wn, werr := dst.Write(buf[:n])
+			written += int64(wn)
+			*remaining -= int64(wn)
+			if werr != nil {
+				return written, werr
+			}
+		}
+		if rerr == io.EOF {
+			return written, nil
+		}
+		if rerr != nil {
+			return written, rerr
+		}
+	}
+}
+
+// ArchiveEntry streams a single named entry out of a stored .zip archive,
+// writing its decompressed contents to w. entry is base64-encoded to allow
+// arbitrary path separators and bytes to be passed safely in a URL path
+// segment.
+func (t *Tools) ArchiveEntry(archivePath, entry string, w io.Writer) error {
+	name, err := base64.URLEncoding.DecodeString(entry)
+	if err != nil {
+		return err
+	}
+
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		if f.Name != string(name) {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+
+		_, err = io.Copy(w, rc)
+		return err
+	}
+
+	return fmt.Errorf("entry %q not found in archive", name)
+}
@@ -0,0 +1,125 @@
+package toolkit
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTools_SafeExtractPath(t *testing.T) {
+	uploadDir := t.TempDir()
+	var testTools Tools
+
+	if _, err := testTools.safeExtractPath(uploadDir, "/etc/passwd"); err != ErrZipSlip {
+		t.Errorf("absolute path: got %v, want ErrZipSlip", err)
+	}
+
+	if _, err := testTools.safeExtractPath(uploadDir, "../../etc/passwd"); err != ErrZipSlip {
+		t.Errorf("path traversal: got %v, want ErrZipSlip", err)
+	}
+
+	got, err := testTools.safeExtractPath(uploadDir, "nested/file.txt")
+	if err != nil {
+		t.Fatalf("safe path rejected: %v", err)
+	}
+	if want := filepath.Join(uploadDir, "nested/file.txt"); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// TestTools_ExtractZip_RejectsZipSlip builds a zip whose only entry resolves
+// outside the destination directory and asserts extractZip rejects it
+// without writing anything outside uploadDir.
+func TestTools_ExtractZip_RejectsZipSlip(t *testing.T) {
+	uploadDir := t.TempDir()
+	archivePath := filepath.Join(t.TempDir(), "slip.zip")
+
+	f, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("../escaped.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("pwned")); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var testTools Tools
+	if _, err := testTools.extractZip(archivePath, uploadDir); err != ErrZipSlip {
+		t.Errorf("got %v, want ErrZipSlip", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(filepath.Dir(uploadDir), "escaped.txt")); !os.IsNotExist(err) {
+		t.Error("zip-slip entry was written outside uploadDir")
+	}
+}
+
+// TestTools_ExtractZip_EnforcesMaxTotalUncompressedSize guards against
+// zip-bomb style archives by capping the sum of extracted entry sizes, and
+// asserts the cap is enforced while writing (not after the fact) and that a
+// rejected archive leaves nothing extracted behind on disk.
+func TestTools_ExtractZip_EnforcesMaxTotalUncompressedSize(t *testing.T) {
+	uploadDir := t.TempDir()
+	archivePath := filepath.Join(t.TempDir(), "bomb.zip")
+
+	f, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+
+	small, err := zw.Create("a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := small.Write([]byte("0123456789")); err != nil {
+		t.Fatal(err)
+	}
+
+	huge, err := zw.Create("b.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hugePayload := bytes.Repeat([]byte("x"), 5*1024*1024) // 5MB, far over the budget below
+	if _, err := huge.Write(hugePayload); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var testTools Tools
+	testTools.MaxTotalUncompressedSize = 15
+
+	if _, err := testTools.extractZip(archivePath, uploadDir); err != ErrArchiveTooLarge {
+		t.Errorf("got %v, want ErrArchiveTooLarge", err)
+	}
+
+	entries, err := os.ReadDir(uploadDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected uploadDir to be empty after rejection, found: %v", entries)
+	}
+
+	destPath := filepath.Join(uploadDir, "b.bin")
+	if fi, err := os.Stat(destPath); !os.IsNotExist(err) {
+		t.Errorf("oversized entry was written to disk (size %v), want no file", fi)
+	}
+}
@@ -0,0 +1,56 @@
+// Package blobstorage provides optional S3 and GCS backed storage.Storage
+// implementations for toolkit uploads, via gocloud.dev/blob. It is not
+// imported by the core toolkit package, so consumers who only use
+// toolkit.LocalStorage don't pull in the AWS/GCS SDKs, gRPC and
+// OpenTelemetry transitively required to talk to those backends.
+package blobstorage
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"gocloud.dev/blob"
+	_ "gocloud.dev/blob/gcsblob"
+	_ "gocloud.dev/blob/s3blob"
+)
+
+// Storage adapts a gocloud.dev/blob bucket as a toolkit.Storage. Use
+// OpenS3Storage or OpenGCSStorage to construct one.
+type Storage struct {
+	Bucket    *blob.Bucket
+	URLPrefix string
+}
+
+// OpenS3Storage opens an S3-compatible bucket, e.g. "s3://my-bucket?region=us-east-1".
+func OpenS3Storage(ctx context.Context, bucketURL, urlPrefix string) (*Storage, error) {
+	bucket, err := blob.OpenBucket(ctx, bucketURL)
+	if err != nil {
+		return nil, fmt.Errorf("opening S3 bucket: %w", err)
+	}
+	return &Storage{Bucket: bucket, URLPrefix: urlPrefix}, nil
+}
+
+// OpenGCSStorage opens a GCS bucket, e.g. "gs://my-bucket".
+func OpenGCSStorage(ctx context.Context, bucketURL, urlPrefix string) (*Storage, error) {
+	bucket, err := blob.OpenBucket(ctx, bucketURL)
+	if err != nil {
+		return nil, fmt.Errorf("opening GCS bucket: %w", err)
+	}
+	return &Storage{Bucket: bucket, URLPrefix: urlPrefix}, nil
+}
+
+// Create implements toolkit.Storage.
+func (s *Storage) Create(ctx context.Context, key string) (io.WriteCloser, error) {
+	return s.Bucket.NewWriter(ctx, key, nil)
+}
+
+// Delete implements toolkit.Storage.
+func (s *Storage) Delete(ctx context.Context, key string) error {
+	return s.Bucket.Delete(ctx, key)
+}
+
+// URL implements toolkit.Storage.
+func (s *Storage) URL(key string) string {
+	return s.URLPrefix + key
+}
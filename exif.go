@@ -0,0 +1,59 @@
+package toolkit
+
+import (
+	"bytes"
+	"errors"
+	"image/jpeg"
+
+	"golang.org/x/image/tiff"
+)
+
+// ErrEXIFDecodeFailed is returned when StripEXIF is enabled and the sniffed
+// content-type claims to be an image format but the bytes do not decode as
+// one, which is typical of a polyglot file spoofing its content-type.
+var ErrEXIFDecodeFailed = errors.New("could not decode image for EXIF stripping")
+
+// stripEXIF decodes an image/jpeg or image/tiff payload and re-encodes it
+// without any metadata, removing EXIF (GPS, camera, thumbnail, etc.) data in
+// the process. filetype must be the sniffed content-type, not a filename
+// extension, so spoofed files are rejected by the decode step rather than
+// trusted.
+func (t *Tools) stripEXIF(data []byte, filetype string) ([]byte, error) {
+	if len(t.AllowedImageExifTypes) > 0 {
+		allowed := false
+		for _, ft := range t.AllowedImageExifTypes {
+			if ft == filetype {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return data, nil
+		}
+	}
+
+	switch filetype {
+	case "image/jpeg":
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, ErrEXIFDecodeFailed
+		}
+		var out bytes.Buffer
+		if err := jpeg.Encode(&out, img, nil); err != nil {
+			return nil, err
+		}
+		return out.Bytes(), nil
+	case "image/tiff":
+		img, err := tiff.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, ErrEXIFDecodeFailed
+		}
+		var out bytes.Buffer
+		if err := tiff.Encode(&out, img, nil); err != nil {
+			return nil, err
+		}
+		return out.Bytes(), nil
+	default:
+		return data, nil
+	}
+}
@@ -0,0 +1,96 @@
+package toolkit
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+// jpegWithEXIF encodes a tiny JPEG and splices a minimal EXIF APP1 segment
+// right after the SOI marker, mimicking what a real camera-originated JPEG
+// looks like on the wire.
+func jpegWithEXIF(t *testing.T) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			img.Set(x, y, color.RGBA{uint8(x * 10), uint8(y * 10), 50, 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	exifPayload := append([]byte("Exif\x00\x00"), []byte{
+		0x4D, 0x4D, 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08, // TIFF header, big-endian
+		0x00, 0x00, // 0 IFD entries
+		0x00, 0x00, 0x00, 0x00, // next IFD offset
+	}...)
+	segLen := len(exifPayload) + 2
+	app1 := []byte{0xFF, 0xE1, byte(segLen >> 8), byte(segLen & 0xFF)}
+	app1 = append(app1, exifPayload...)
+
+	withEXIF := append([]byte{}, data[:2]...) // SOI
+	withEXIF = append(withEXIF, app1...)
+	withEXIF = append(withEXIF, data[2:]...)
+	return withEXIF
+}
+
+func TestTools_StripEXIF_RemovesEXIFSegment(t *testing.T) {
+	withEXIF := jpegWithEXIF(t)
+	if !bytes.Contains(withEXIF, []byte("Exif\x00\x00")) {
+		t.Fatal("test fixture doesn't actually contain an EXIF segment")
+	}
+
+	var testTools Tools
+	cleaned, err := testTools.stripEXIF(withEXIF, "image/jpeg")
+	if err != nil {
+		t.Fatalf("stripEXIF returned an error: %v", err)
+	}
+
+	if bytes.Contains(cleaned, []byte("Exif\x00\x00")) {
+		t.Error("cleaned JPEG still contains its EXIF segment")
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(cleaned)); err != nil {
+		t.Errorf("cleaned JPEG doesn't decode: %v", err)
+	}
+}
+
+func TestTools_StripEXIF_RejectsSpoofedContentType(t *testing.T) {
+	var testTools Tools
+	notAnImage := []byte("this is plain text, not image bytes")
+
+	_, err := testTools.stripEXIF(notAnImage, "image/jpeg")
+	if err != ErrEXIFDecodeFailed {
+		t.Errorf("got %v, want ErrEXIFDecodeFailed", err)
+	}
+}
+
+func TestTools_StripEXIF_AllowedImageExifTypesRestrictsStripping(t *testing.T) {
+	withEXIF := jpegWithEXIF(t)
+
+	var testTools Tools
+	testTools.AllowedImageExifTypes = []string{"image/tiff"} // jpeg not in the allow-list
+
+	out, err := testTools.stripEXIF(withEXIF, "image/jpeg")
+	if err != nil {
+		t.Fatalf("stripEXIF returned an error: %v", err)
+	}
+	if !bytes.Equal(out, withEXIF) {
+		t.Error("stripEXIF modified a filetype not listed in AllowedImageExifTypes")
+	}
+
+	testTools.AllowedImageExifTypes = []string{"image/jpeg"}
+	out, err = testTools.stripEXIF(withEXIF, "image/jpeg")
+	if err != nil {
+		t.Fatalf("stripEXIF returned an error: %v", err)
+	}
+	if bytes.Contains(out, []byte("Exif\x00\x00")) {
+		t.Error("stripEXIF left the EXIF segment in place for an allowed filetype")
+	}
+}
@@ -0,0 +1,99 @@
+package toolkit
+
+import (
+	"errors"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// ErrInjectedClientParam is returned when a client-supplied multipart form
+// field name collides with one of the reserved names configured via
+// Tools.ReservedFieldNames.
+var ErrInjectedClientParam = errors.New("form field name collides with a reserved parameter")
+
+// parsedDisposition holds the pieces of a Content-Disposition header that
+// matter for upload handling.
+type parsedDisposition struct {
+	FieldName string
+	Filename  string
+}
+
+// parseContentDisposition parses a part's raw Content-Disposition header,
+// preferring the RFC 5987 filename* parameter (which carries an explicit
+// charset, typically UTF-8) over the plain filename parameter.
+func parseContentDisposition(header string) (parsedDisposition, error) {
+	_, params, err := mime.ParseMediaType(header)
+	if err != nil {
+		return parsedDisposition{}, err
+	}
+
+	var pd parsedDisposition
+	pd.FieldName = params["name"]
+	pd.Filename = params["filename"]
+
+	if encoded, ok := params["filename*"]; ok {
+		if decoded, err := decodeRFC5987(encoded); err == nil {
+			pd.Filename = decoded
+		}
+	}
+
+	return pd, nil
+}
+
+// decodeRFC5987 decodes an ext-value of the form charset'lang'value, as used
+// by the filename* Content-Disposition parameter.
+func decodeRFC5987(value string) (string, error) {
+	parts := strings.SplitN(value, "'", 3)
+	if len(parts) != 3 {
+		return "", errors.New("malformed RFC 5987 value")
+	}
+	charset, encoded := strings.ToLower(parts[0]), parts[2]
+	if charset != "utf-8" {
+		return "", errors.New("unsupported charset in filename*")
+	}
+	return url.QueryUnescape(encoded)
+}
+
+// sanitizeFilename resolves the safe on-disk filename for an uploaded part,
+// using its Content-Disposition header rather than trusting hdr.Filename
+// verbatim. It rejects path traversal, directory separators, empty names,
+// and form field names that collide with t.ReservedFieldNames.
+func (t *Tools) sanitizeFilename(hdr *multipart.FileHeader, uploadDir string) (string, error) {
+	pd, err := parseContentDisposition(hdr.Header.Get("Content-Disposition"))
+	if err != nil {
+		// fall back to the parsed values multipart already gives us
+		pd = parsedDisposition{FieldName: "", Filename: hdr.Filename}
+	}
+	if pd.Filename == "" {
+		pd.Filename = hdr.Filename
+	}
+
+	return t.sanitizeFieldAndFilename(pd.FieldName, pd.Filename, uploadDir)
+}
+
+// sanitizeFieldAndFilename applies the same field-name and filename checks
+// as sanitizeFilename, for callers that read a multipart.Part directly
+// instead of a *multipart.FileHeader (e.g. StreamUploadFiles).
+func (t *Tools) sanitizeFieldAndFilename(fieldName, filename, uploadDir string) (string, error) {
+	for _, reserved := range t.ReservedFieldNames {
+		if fieldName == reserved {
+			return "", ErrInjectedClientParam
+		}
+	}
+
+	name := filepath.Base(strings.ReplaceAll(filename, "\\", "/"))
+	if name == "" || name == "." || name == string(filepath.Separator) {
+		return "", errors.New("empty or invalid filename")
+	}
+
+	destPath := filepath.Join(uploadDir, name)
+	cleanUploadDir := filepath.Clean(uploadDir) + string(filepath.Separator)
+	if !strings.HasPrefix(filepath.Clean(destPath)+string(filepath.Separator), cleanUploadDir) {
+		return "", errors.New("filename resolves outside upload directory")
+	}
+
+	return name, nil
+}
@@ -0,0 +1,94 @@
+package toolkit
+
+import "testing"
+
+func TestParseContentDisposition(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    parsedDisposition
+		wantErr bool
+	}{
+		{
+			name:   "plain filename",
+			header: `form-data; name="file"; filename="report.pdf"`,
+			want:   parsedDisposition{FieldName: "file", Filename: "report.pdf"},
+		},
+		{
+			name:   "rfc5987 filename* takes precedence over filename",
+			header: `form-data; name="file"; filename="fallback.txt"; filename*=UTF-8''caf%C3%A9.txt`,
+			want:   parsedDisposition{FieldName: "file", Filename: "café.txt"},
+		},
+		{
+			name:    "malformed header",
+			header:  `not a valid media type;;;`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseContentDisposition(tc.header)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got != tc.want {
+				t.Errorf("got %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTools_SanitizeFieldAndFilename(t *testing.T) {
+	uploadDir := t.TempDir()
+	var testTools Tools
+	testTools.ReservedFieldNames = []string{".file", ".path"}
+
+	tests := []struct {
+		name      string
+		fieldName string
+		filename  string
+	}{
+		{name: "ordinary filename", fieldName: "file", filename: "photo.jpg"},
+		{name: "path traversal", fieldName: "file", filename: "../../etc/passwd"},
+		{name: "windows-style traversal", fieldName: "file", filename: `..\..\secrets.txt`},
+		{name: "empty filename", fieldName: "file", filename: ""},
+		{name: "reserved field name", fieldName: ".file", filename: "photo.jpg"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			name, err := testTools.sanitizeFieldAndFilename(tc.fieldName, tc.filename, uploadDir)
+			if tc.name == "reserved field name" {
+				if err != ErrInjectedClientParam {
+					t.Fatalf("got %v, want ErrInjectedClientParam", err)
+				}
+				return
+			}
+			if tc.name == "empty filename" {
+				if err == nil {
+					t.Fatal("expected an error for an empty filename")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if containsPathSeparator(name) {
+				t.Errorf("sanitized name %q still contains a directory separator", name)
+			}
+		})
+	}
+}
+
+func containsPathSeparator(s string) bool {
+	for _, r := range s {
+		if r == '/' || r == '\\' {
+			return true
+		}
+	}
+	return false
+}
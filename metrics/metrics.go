@@ -0,0 +1,72 @@
+// Package metrics provides optional Prometheus instrumentation for toolkit
+// uploads. It is not imported by the core toolkit package; wire a Collector
+// into Tools.Metrics to opt in.
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Collector holds the Prometheus collectors backing toolkit's upload
+// metrics. Construct one with NewCollector and assign it to Tools.Metrics;
+// toolkit.Tools only depends on the toolkit.Metrics interface it satisfies,
+// so importing this package never forces a consumer to pull in
+// prometheus/client_golang or register anything on the default registry.
+type Collector struct {
+	// UploadRequestsTotal counts upload attempts by outcome. result is
+	// "success" or a rejection reason such as "too_large", "bad_type", or
+	// "injected_param".
+	UploadRequestsTotal *prometheus.CounterVec
+
+	// UploadBytesTotal sums uploaded bytes by sniffed content-type.
+	UploadBytesTotal *prometheus.CounterVec
+
+	// UploadFilesTotal counts uploaded files by sniffed content-type.
+	UploadFilesTotal *prometheus.CounterVec
+
+	// UploadDurationSeconds observes the wall-clock time of each upload call.
+	UploadDurationSeconds prometheus.Histogram
+}
+
+// NewCollector creates toolkit's upload collectors and registers them with
+// reg. Pass prometheus.DefaultRegisterer to use the default registry, or any
+// other Registerer (e.g. a test-local prometheus.NewRegistry()) to keep
+// toolkit's metrics isolated.
+func NewCollector(reg prometheus.Registerer) *Collector {
+	c := &Collector{
+		UploadRequestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "toolkit_upload_requests_total",
+			Help: "Total number of upload requests handled by toolkit, by result.",
+		}, []string{"result"}),
+		UploadBytesTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "toolkit_upload_bytes_total",
+			Help: "Total bytes uploaded through toolkit, by sniffed filetype.",
+		}, []string{"filetype"}),
+		UploadFilesTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "toolkit_upload_files_total",
+			Help: "Total files uploaded through toolkit, by sniffed filetype.",
+		}, []string{"filetype"}),
+		UploadDurationSeconds: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name: "toolkit_upload_duration_seconds",
+			Help: "Duration of toolkit upload requests in seconds.",
+		}),
+	}
+	reg.MustRegister(c.UploadRequestsTotal, c.UploadBytesTotal, c.UploadFilesTotal, c.UploadDurationSeconds)
+	return c
+}
+
+// ObserveResult increments UploadRequestsTotal for the given result label,
+// e.g. "success", "too_large", "bad_type", "injected_param".
+func (c *Collector) ObserveResult(result string) {
+	c.UploadRequestsTotal.WithLabelValues(result).Inc()
+}
+
+// ObserveFile records one successfully uploaded file of the given sniffed
+// filetype and size in bytes.
+func (c *Collector) ObserveFile(filetype string, size int64) {
+	c.UploadBytesTotal.WithLabelValues(filetype).Add(float64(size))
+	c.UploadFilesTotal.WithLabelValues(filetype).Inc()
+}
+
+// ObserveDuration records how long an upload call took, in seconds.
+func (c *Collector) ObserveDuration(seconds float64) {
+	c.UploadDurationSeconds.Observe(seconds)
+}
@@ -0,0 +1,74 @@
+package toolkit
+
+import "encoding/hex"
+
+// Metrics is implemented by optional instrumentation sinks, such as
+// metrics.Collector, that UploadFiles reports upload outcomes to. Tools.Metrics
+// is nil-checked like Tools.Storage and Tools.Logger, so toolkit's core
+// upload path never depends on the metrics subpackage or any particular
+// instrumentation library.
+type Metrics interface {
+	// ObserveResult is called once per UploadFiles call with its outcome,
+	// e.g. "success", "too_large", "bad_type", "injected_param".
+	ObserveResult(result string)
+
+	// ObserveFile is called once per successfully uploaded file with its
+	// sniffed content-type and size in bytes.
+	ObserveFile(filetype string, size int64)
+
+	// ObserveDuration is called once per UploadFiles call with its
+	// wall-clock duration in seconds.
+	ObserveDuration(seconds float64)
+}
+
+// observeResult reports result to t.Metrics, when set.
+func (t *Tools) observeResult(result string) {
+	if t.Metrics == nil {
+		return
+	}
+	t.Metrics.ObserveResult(result)
+}
+
+// observeFile reports a successfully uploaded file to t.Metrics, when set.
+func (t *Tools) observeFile(filetype string, size int64) {
+	if t.Metrics == nil {
+		return
+	}
+	t.Metrics.ObserveFile(filetype, size)
+}
+
+// observeDuration reports an UploadFiles call's duration to t.Metrics, when set.
+func (t *Tools) observeDuration(seconds float64) {
+	if t.Metrics == nil {
+		return
+	}
+	t.Metrics.ObserveDuration(seconds)
+}
+
+// logUpload emits an audit record for one successfully uploaded file, when
+// t.Logger is set. The current code is otherwise entirely silent on success.
+func (t *Tools) logUpload(f *UploadedFile, filetype string, sha256sum []byte) {
+	if t.Logger == nil {
+		return
+	}
+	t.Logger.Info("toolkit upload",
+		"original_filename", f.OriginalFilename,
+		"sanitized_filename", f.NewFilename,
+		"size_bytes", f.FileSize,
+		"filetype", filetype,
+		"sha256", hex.EncodeToString(sha256sum),
+	)
+}
+
+// logReject emits an audit record for a rejected upload, when t.Logger is
+// set. The current code is otherwise entirely silent on failure paths.
+func (t *Tools) logReject(originalFilename, reason string, cause error) {
+	if t.Logger == nil {
+		return
+	}
+	t.Logger.Warn("toolkit upload rejected",
+		"original_filename", originalFilename,
+		"reason", reason,
+		"error", cause,
+	)
+}
@@ -0,0 +1,60 @@
+package toolkit
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Storage is the destination an uploaded file is written to. The default,
+// LocalStorage, preserves the toolkit's original behaviour of writing to a
+// directory on local disk. For S3-compatible object stores and GCS, see the
+// blobstorage subpackage, which keeps the toolkit package free of the AWS
+// and GCS SDKs for consumers who don't need them.
+type Storage interface {
+	// Create opens key for writing. The caller must Close the returned
+	// writer to flush and finalize the write.
+	Create(ctx context.Context, key string) (io.WriteCloser, error)
+
+	// Delete removes key, for cleaning up a partial or rejected upload.
+	Delete(ctx context.Context, key string) error
+
+	// URL returns a reference to key suitable for the storage's transport
+	// (a filesystem path for LocalStorage, a blob URL for blobstorage.Storage).
+	URL(key string) string
+}
+
+// LocalStorage writes uploads to a directory on local disk. It is the
+// default Storage used by UploadFiles when Tools.Storage is nil.
+type LocalStorage struct {
+	Dir string
+}
+
+// Create implements Storage.
+func (l *LocalStorage) Create(_ context.Context, key string) (io.WriteCloser, error) {
+	path := filepath.Join(l.Dir, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	return os.Create(path)
+}
+
+// Delete implements Storage.
+func (l *LocalStorage) Delete(_ context.Context, key string) error {
+	return os.Remove(filepath.Join(l.Dir, key))
+}
+
+// URL implements Storage.
+func (l *LocalStorage) URL(key string) string {
+	return filepath.Join(l.Dir, key)
+}
+
+// storage returns t.Storage if set, otherwise a LocalStorage rooted at
+// uploadDir, preserving UploadFiles' original on-disk behaviour.
+func (t *Tools) storage(uploadDir string) Storage {
+	if t.Storage != nil {
+		return t.Storage
+	}
+	return &LocalStorage{Dir: uploadDir}
+}
@@ -0,0 +1,123 @@
+package toolkit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+)
+
+// failingWriteCloser returns an error from Write, simulating a mid-copy
+// failure against a remote Storage backend such as S3.
+type failingWriteCloser struct{}
+
+func (failingWriteCloser) Write(_ []byte) (int, error) { return 0, errors.New("write failed") }
+func (failingWriteCloser) Close() error                { return nil }
+
+// fakeStorage records whether Delete was called for a given key, so tests
+// can assert that a failed upload is cleaned up rather than left behind.
+type fakeStorage struct {
+	deleted map[string]bool
+}
+
+func (f *fakeStorage) Create(_ context.Context, _ string) (io.WriteCloser, error) {
+	return failingWriteCloser{}, nil
+}
+
+func (f *fakeStorage) Delete(_ context.Context, key string) error {
+	if f.deleted == nil {
+		f.deleted = make(map[string]bool)
+	}
+	f.deleted[key] = true
+	return nil
+}
+
+func (f *fakeStorage) URL(key string) string { return key }
+
+// TestTools_UploadFiles_DeletesPartialObjectOnCopyFailure guards against a
+// regression where a mid-copy write failure against Tools.Storage left a
+// partial object behind because only the StripEXIF failure path cleaned up
+// after itself.
+func TestTools_UploadFiles_DeletesPartialObjectOnCopyFailure(t *testing.T) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", "blob.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := part.Write([]byte("hello world")); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest("POST", "/", &body)
+	r.Header.Add("Content-Type", writer.FormDataContentType())
+
+	storage := &fakeStorage{}
+	var testTools Tools
+	testTools.AllowedFileTypes = []string{"text/plain; charset=utf-8"}
+	testTools.Storage = storage
+
+	if _, err := testTools.UploadFiles(r, t.TempDir(), false); err == nil {
+		t.Fatal("expected an error from the failing storage backend")
+	}
+
+	if len(storage.deleted) != 1 {
+		t.Errorf("expected the partial object to be deleted, got deletes: %v", storage.deleted)
+	}
+}
+
+// TestTools_UploadFiles_DeletesPartialObjectOnEXIFWriteFailure guards
+// against a regression where the StripEXIF branch's final outfile.Write
+// failure (writing the cleaned bytes to Tools.Storage) left a partial
+// object behind, unlike the stripEXIF decode failure right above it.
+func TestTools_UploadFiles_DeletesPartialObjectOnEXIFWriteFailure(t *testing.T) {
+	var jpegBuf bytes.Buffer
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			img.Set(x, y, color.RGBA{uint8(x * 50), uint8(y * 50), 100, 255})
+		}
+	}
+	if err := jpeg.Encode(&jpegBuf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", "photo.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := part.Write(jpegBuf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest("POST", "/", &body)
+	r.Header.Add("Content-Type", writer.FormDataContentType())
+
+	storage := &fakeStorage{}
+	var testTools Tools
+	testTools.AllowedFileTypes = []string{"image/jpeg"}
+	testTools.StripEXIF = true
+	testTools.Storage = storage
+
+	if _, err := testTools.UploadFiles(r, t.TempDir(), false); err == nil {
+		t.Fatal("expected an error from the failing storage backend")
+	}
+
+	if len(storage.deleted) != 1 {
+		t.Errorf("expected the partial object to be deleted, got deletes: %v", storage.deleted)
+	}
+}
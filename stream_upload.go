@@ -0,0 +1,170 @@
+package toolkit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrTooManyFiles is returned by StreamUploadFiles when the number of parts
+// in the multipart request exceeds Tools.MaxFilesAllowed.
+var ErrTooManyFiles = errors.New("too many files in upload")
+
+// PartInfo describes the form field and file currently being streamed to a
+// PartHandler registered via Tools.Register.
+type PartInfo struct {
+	FieldName   string
+	Filename    string
+	ContentType string
+}
+
+// PartHandler is called once per uploaded part when a handler has been
+// registered for its form field via Tools.Register. Implementations can
+// stream r directly to S3/blobstore/etc. without materializing the upload
+// on local disk. r is capped at PerFileMaxSize bytes (when set), same as the
+// disk-write path; reading past the limit returns io.EOF early and
+// streamOnePart reports the oversized part as an error once fn returns.
+type PartHandler func(r io.Reader, info PartInfo) error
+
+// Register associates a PartHandler with a multipart form field name. When
+// StreamUploadFiles encounters a part whose field matches, it hands the part
+// to fn instead of writing it to uploadDir.
+func (t *Tools) Register(field string, fn PartHandler) {
+	if t.partHandlers == nil {
+		t.partHandlers = make(map[string]PartHandler)
+	}
+	t.partHandlers[field] = fn
+}
+
+// StreamUploadFiles uploads files from a multipart-form data POST request one
+// part at a time via r.MultipartReader, so the request body is never
+// buffered in full the way ParseMultipartForm buffers it in UploadFiles.
+// Each part is limited to PerFileMaxSize bytes (when set) and the number of
+// parts is limited to MaxFilesAllowed (when set).
+func (t *Tools) StreamUploadFiles(r *http.Request, uploadDir string, shouldRenameFile bool) ([]*UploadedFile, error) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	var uploadedFiles []*UploadedFile
+	var fileCount int
+
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return uploadedFiles, err
+		}
+
+		if part.FileName() == "" {
+			part.Close()
+			continue
+		}
+
+		if t.MaxFilesAllowed > 0 && fileCount >= t.MaxFilesAllowed {
+			part.Close()
+			return uploadedFiles, ErrTooManyFiles
+		}
+		fileCount++
+
+		uploadedFile, err := t.streamOnePart(part, uploadDir, shouldRenameFile)
+		part.Close()
+		if err != nil {
+			return uploadedFiles, err
+		}
+		if uploadedFile != nil {
+			uploadedFiles = append(uploadedFiles, uploadedFile)
+		}
+	}
+
+	return uploadedFiles, nil
+}
+
+// streamOnePart validates, sizes and either dispatches or writes a single
+// multipart part.
+func (t *Tools) streamOnePart(part *multipart.Part, uploadDir string, shouldRenameFile bool) (*UploadedFile, error) {
+	buff := make([]byte, 512)
+	n, err := io.ReadFull(part, buff)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	buff = buff[:n]
+	filetype := http.DetectContentType(buff)
+
+	isFileAllowed := len(t.AllowedFileTypes) == 0
+	for _, ft := range t.AllowedFileTypes {
+		if strings.EqualFold(filetype, ft) {
+			isFileAllowed = true
+		}
+	}
+	if !isFileAllowed {
+		return nil, errors.New("uploading filetype is not permitted")
+	}
+
+	rest := io.MultiReader(strings.NewReader(string(buff)), part)
+
+	if fn, registered := t.partHandlers[part.FormName()]; registered {
+		info := PartInfo{FieldName: part.FormName(), Filename: part.FileName(), ContentType: filetype}
+
+		var limited *io.LimitedReader
+		src := rest
+		if t.PerFileMaxSize > 0 {
+			limited = &io.LimitedReader{R: rest, N: int64(t.PerFileMaxSize) + 1}
+			src = limited
+		}
+
+		if err := fn(src, info); err != nil {
+			return nil, err
+		}
+		if limited != nil && limited.N <= 0 {
+			return nil, errors.New("uploaded file exceeds per-file maximum size")
+		}
+		return nil, nil
+	}
+
+	safeName, err := t.sanitizeFieldAndFilename(part.FormName(), part.FileName(), uploadDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var uploadedFile UploadedFile
+	uploadedFile.OriginalFilename = part.FileName()
+	if shouldRenameFile {
+		uploadedFile.NewFilename = fmt.Sprintf("%s%s", t.RandomString(25), filepath.Ext(safeName))
+	} else {
+		uploadedFile.NewFilename = safeName
+	}
+
+	destPath := filepath.Join(uploadDir, uploadedFile.NewFilename)
+	outfile, err := os.Create(destPath)
+	if err != nil {
+		return nil, err
+	}
+	defer outfile.Close()
+
+	src := rest
+	if t.PerFileMaxSize > 0 {
+		src = &io.LimitedReader{R: rest, N: int64(t.PerFileMaxSize) + 1}
+	}
+
+	filesize, err := io.Copy(outfile, src)
+	if err != nil {
+		os.Remove(destPath)
+		return nil, err
+	}
+	if t.PerFileMaxSize > 0 && filesize > int64(t.PerFileMaxSize) {
+		os.Remove(destPath)
+		return nil, errors.New("uploaded file exceeds per-file maximum size")
+	}
+	uploadedFile.FileSize = filesize
+
+	return &uploadedFile, nil
+}
@@ -0,0 +1,48 @@
+package toolkit
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestTools_StreamUploadFiles_HandlerRespectsPerFileMaxSize guards against a
+// regression where a part routed to a registered PartHandler bypassed
+// PerFileMaxSize entirely, since only the disk-write path enforced it.
+func TestTools_StreamUploadFiles_HandlerRespectsPerFileMaxSize(t *testing.T) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", "blob.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := part.Write(bytes.Repeat([]byte("x"), 1024)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest("POST", "/", &body)
+	r.Header.Add("Content-Type", writer.FormDataContentType())
+
+	var testTools Tools
+	testTools.PerFileMaxSize = 100
+
+	var streamed int64
+	testTools.Register("file", func(rdr io.Reader, _ PartInfo) error {
+		n, _ := io.Copy(io.Discard, rdr)
+		streamed = n
+		return nil
+	})
+
+	_, err = testTools.StreamUploadFiles(r, t.TempDir(), false)
+	if err == nil {
+		t.Fatal("expected an error for a part exceeding PerFileMaxSize")
+	}
+	if streamed > int64(testTools.PerFileMaxSize)+1 {
+		t.Errorf("handler read %d bytes, want at most %d", streamed, testTools.PerFileMaxSize+1)
+	}
+}
@@ -2,13 +2,15 @@ package toolkit
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
-	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 const randomStringSource = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_+"
@@ -18,6 +20,48 @@ const randomStringSource = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ
 type Tools struct {
 	MaxFileSize      int
 	AllowedFileTypes []string
+
+	// PerFileMaxSize caps the size, in bytes, of any single part read by
+	// StreamUploadFiles. Zero means no per-file limit.
+	PerFileMaxSize int
+
+	// MaxFilesAllowed caps the number of file parts StreamUploadFiles will
+	// accept from a single request. Zero means no limit.
+	MaxFilesAllowed int
+
+	// StripEXIF, when true, re-encodes uploaded image/jpeg and image/tiff
+	// files without their metadata before writing them to disk.
+	StripEXIF bool
+
+	// AllowedImageExifTypes restricts StripEXIF to the listed content-types.
+	// When empty, StripEXIF applies to all image/jpeg and image/tiff uploads.
+	AllowedImageExifTypes []string
+
+	// MaxTotalUncompressedSize caps the sum of all entry sizes extracted by
+	// UploadAndExtractArchive, guarding against zip-bomb style archives.
+	// Zero means no limit.
+	MaxTotalUncompressedSize int
+
+	// ReservedFieldNames lists multipart form field names that clients must
+	// never supply (e.g. ".file", ".path"). A request using one of them
+	// fails with ErrInjectedClientParam.
+	ReservedFieldNames []string
+
+	// Storage is the destination uploaded files are written to. When nil,
+	// UploadFiles falls back to a LocalStorage rooted at the uploadDir
+	// passed to it.
+	Storage Storage
+
+	// Logger, when set, receives one audit record per upload attempt
+	// (original name, sanitized name, size, sha256) and one on failure.
+	Logger *slog.Logger
+
+	// Metrics, when set, receives upload outcome, byte/file, and duration
+	// observations. See metrics.Collector for a Prometheus-backed
+	// implementation.
+	Metrics Metrics
+
+	partHandlers map[string]PartHandler
 }
 
 // RandomString returns a string of random characters of length n, using randomStringSource
@@ -37,6 +81,14 @@ type UploadedFile struct {
 	NewFilename      string
 	OriginalFilename string
 	FileSize         int64
+
+	// StorageKey is the key the file was written under in Tools.Storage.
+	StorageKey string
+
+	// PublicURL is the storage's reference to StorageKey, as returned by
+	// Storage.URL (a filesystem path for LocalStorage, a blob URL for
+	// blobstorage.Storage).
+	PublicURL string
 }
 
 // Uploads a single file from a multipart-form data POST request
@@ -50,9 +102,17 @@ func (t *Tools) UploadOneFile(r *http.Request, uploadDir string, shouldRenameFil
 
 // Uploads files from a multipart-form data POST request
 func (t *Tools) UploadFiles(r *http.Request, uploadDir string, shouldRenameFile bool) ([]*UploadedFile, error) {
+	start := time.Now()
+	result := "success"
+	defer func() {
+		t.observeDuration(time.Since(start).Seconds())
+		t.observeResult(result)
+	}()
+
 	// 1: Parse the request body as multipart-form data
 	err := r.ParseMultipartForm(int64(t.MaxFileSize))
 	if err != nil {
+		result = "too_large"
 		return nil, errors.New("file size is too large")
 	}
 
@@ -69,12 +129,14 @@ func (t *Tools) UploadFiles(r *http.Request, uploadDir string, shouldRenameFile
 				defer infile.Close()
 
 				buff := make([]byte, 512)
-				_, err = infile.Read(buff)
+				n, err := infile.Read(buff)
 				if err != nil {
 					return nil, err
 				}
+				buff = buff[:n]
 
 				filetype := http.DetectContentType(buff)
+				rest := io.MultiReader(strings.NewReader(string(buff)), infile)
 				isFileAllowed := false
 
 				if len(t.AllowedFileTypes) > 0 {
@@ -86,31 +148,82 @@ func (t *Tools) UploadFiles(r *http.Request, uploadDir string, shouldRenameFile
 				}
 
 				if !isFileAllowed {
+					result = "bad_type"
+					t.logReject(hdr.Filename, result, errors.New("uploading filetype is not permitted"))
 					return nil, errors.New("uploading filetype is not permitted")
 				}
 
 				// 4: Rename when necessary
+				safeName, err := t.sanitizeFilename(hdr, uploadDir)
+				if err != nil {
+					if errors.Is(err, ErrInjectedClientParam) {
+						result = "injected_param"
+					} else {
+						result = "bad_filename"
+					}
+					t.logReject(hdr.Filename, result, err)
+					return nil, err
+				}
+
 				var uploadedFile UploadedFile
 				uploadedFile.OriginalFilename = hdr.Filename
 
 				if shouldRenameFile {
-					uploadedFile.NewFilename = fmt.Sprintf("%s%s", t.RandomString(25), filepath.Ext(hdr.Filename))
+					uploadedFile.NewFilename = fmt.Sprintf("%s%s", t.RandomString(25), filepath.Ext(safeName))
 				} else {
-					uploadedFile.NewFilename = hdr.Filename
+					uploadedFile.NewFilename = safeName
 				}
 
-				// 5: Write to disk
-				var outfile *os.File
-				if outfile, err = os.Create(filepath.Join(uploadDir, uploadedFile.NewFilename)); err != nil {
+				// 5: Write to storage (local disk by default, or t.Storage when set)
+				dest := t.storage(uploadDir)
+				uploadedFile.StorageKey = uploadedFile.NewFilename
+
+				outfile, err := dest.Create(r.Context(), uploadedFile.StorageKey)
+				if err != nil {
 					return nil, err
+				}
+
+				hasher := sha256.New()
+
+				if t.StripEXIF && (filetype == "image/jpeg" || filetype == "image/tiff") {
+					data, err := io.ReadAll(rest)
+					if err != nil {
+						outfile.Close()
+						_ = dest.Delete(r.Context(), uploadedFile.StorageKey)
+						return nil, err
+					}
+					cleaned, err := t.stripEXIF(data, filetype)
+					if err != nil {
+						outfile.Close()
+						_ = dest.Delete(r.Context(), uploadedFile.StorageKey)
+						return nil, err
+					}
+					hasher.Write(cleaned)
+					n, err := outfile.Write(cleaned)
+					if err != nil {
+						outfile.Close()
+						_ = dest.Delete(r.Context(), uploadedFile.StorageKey)
+						return nil, err
+					}
+					uploadedFile.FileSize = int64(n)
 				} else {
-					filesize, err := io.Copy(outfile, infile)
+					filesize, err := io.Copy(outfile, io.TeeReader(rest, hasher))
 					if err != nil {
+						outfile.Close()
+						_ = dest.Delete(r.Context(), uploadedFile.StorageKey)
 						return nil, err
 					}
 					uploadedFile.FileSize = filesize
 				}
 
+				if err := outfile.Close(); err != nil {
+					return nil, err
+				}
+				uploadedFile.PublicURL = dest.URL(uploadedFile.StorageKey)
+
+				t.observeFile(filetype, uploadedFile.FileSize)
+				t.logUpload(&uploadedFile, filetype, hasher.Sum(nil))
+
 				uploadedFiles = append(uploadedFiles, &uploadedFile)
 				return uploadedFiles, nil
 			}(uploadedFiles)
@@ -1,6 +1,7 @@
 package toolkit
 
 import (
+	"bytes"
 	"fmt"
 	"image"
 	"image/png"
@@ -10,6 +11,10 @@ import (
 	"os"
 	"sync"
 	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/takiguri/toolkit/metrics"
 )
 
 func TestTools_RandomString(t *testing.T) {
@@ -111,6 +116,95 @@ func TestTools_UploadFiles(t *testing.T) {
 	}
 }
 
+// TestTools_UploadFiles_PreservesContent guards against a regression where
+// the 512 bytes read while sniffing the content-type were never replayed
+// into the copy to storage, silently truncating the start of every upload.
+func TestTools_UploadFiles_PreservesContent(t *testing.T) {
+	want := bytes.Repeat([]byte("abcdefghij"), 201) // 2010 bytes, > the 512-byte sniff window
+
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+
+	go func() {
+		defer writer.Close()
+		defer wg.Done()
+
+		part, err := writer.CreateFormFile("file", "blob.bin")
+		if err != nil {
+			t.Error(err)
+		}
+		if _, err := part.Write(want); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	r := httptest.NewRequest("POST", "/", pr)
+	r.Header.Add("Content-Type", writer.FormDataContentType())
+
+	var testTools Tools
+	testTools.AllowedFileTypes = []string{"text/plain; charset=utf-8"}
+
+	uploadedFiles, err := testTools.UploadFiles(r, "./testdata/uploads/", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(fmt.Sprintf("./testdata/uploads/%s", uploadedFiles[0].NewFilename))
+
+	got, err := os.ReadFile(fmt.Sprintf("./testdata/uploads/%s", uploadedFiles[0].NewFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("uploaded content corrupted: got %d bytes, want %d bytes", len(got), len(want))
+	}
+
+	wg.Wait()
+}
+
+// TestTools_UploadFiles_Metrics verifies that a Tools.Metrics sink is
+// reported to when set, and that leaving it nil (the default) is a no-op
+// rather than a panic, since UploadFiles no longer depends on the metrics
+// subpackage or the default Prometheus registry.
+func TestTools_UploadFiles_Metrics(t *testing.T) {
+	collector := metrics.NewCollector(prometheus.NewRegistry())
+
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	go func() {
+		defer writer.Close()
+		part, err := writer.CreateFormFile("file", "blob.bin")
+		if err != nil {
+			t.Error(err)
+		}
+		if _, err := part.Write([]byte("hello")); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	r := httptest.NewRequest("POST", "/", pr)
+	r.Header.Add("Content-Type", writer.FormDataContentType())
+
+	var testTools Tools
+	testTools.Metrics = collector
+	testTools.AllowedFileTypes = []string{"text/plain; charset=utf-8"}
+
+	uploadedFiles, err := testTools.UploadFiles(r, "./testdata/uploads/", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(fmt.Sprintf("./testdata/uploads/%s", uploadedFiles[0].NewFilename))
+
+	var m dto.Metric
+	if err := collector.UploadRequestsTotal.WithLabelValues("success").Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetCounter().GetValue(); got != 1 {
+		t.Errorf("UploadRequestsTotal{success} = %v, want 1", got)
+	}
+}
+
 func TestTools_UploadOneFile(t *testing.T) {
 	// set up a pipe to avoid buffering
 	pr, pw := io.Pipe()